@@ -0,0 +1,93 @@
+package httpcache
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The following two headers record, on a stored response, the wall
+// clock time just before the request was sent to the origin and just
+// after the response was received. They are internal bookkeeping,
+// stripped before a response is handed back to a caller, and are only
+// ever read back from a response loaded from `Storage`.
+const (
+	headerRequestTime  = "X-Httpcache-Request-Time"
+	headerResponseTime = "X-Httpcache-Response-Time"
+)
+
+// SetTimestamps records the request/response round-trip times on
+// resp, for later use by `age`.
+func setTimestamps(resp *http.Response, requestTime, responseTime time.Time) {
+	resp.Header.Set(headerRequestTime, requestTime.UTC().Format(time.RFC3339Nano))
+	resp.Header.Set(headerResponseTime, responseTime.UTC().Format(time.RFC3339Nano))
+}
+
+// StripTimestamps removes the bookkeeping headers set by
+// `setTimestamps`, so they never reach a caller.
+func stripTimestamps(resp *http.Response) {
+	resp.Header.Del(headerRequestTime)
+	resp.Header.Del(headerResponseTime)
+}
+
+// Age computes the response's current age, per
+// https://tools.ietf.org/html/rfc7234#section-4.2.3.
+//
+// When resp was never round-tripped through the cache (no recorded
+// timestamps - e.g. a hand-built response in a test), it falls back
+// to the time elapsed since its Date header.
+func age(resp *http.Response) time.Duration {
+	var dateValue, hasDate = date(resp.Header)
+	var responseTime, hasResponseTime = timestamp(resp.Header, headerResponseTime)
+
+	if !hasResponseTime {
+		if hasDate {
+			return time.Since(dateValue)
+		}
+		return 0
+	}
+
+	var ageValue time.Duration
+	if v := resp.Header.Get("Age"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n >= 0 {
+			ageValue = time.Duration(n) * time.Second
+		}
+	}
+
+	var apparentAge time.Duration
+	if hasDate {
+		if apparentAge = responseTime.Sub(dateValue); apparentAge < 0 {
+			apparentAge = 0
+		}
+	}
+
+	var responseDelay time.Duration
+	if requestTime, ok := timestamp(resp.Header, headerRequestTime); ok {
+		responseDelay = responseTime.Sub(requestTime)
+	}
+
+	var correctedAgeValue = ageValue + responseDelay
+	var correctedInitialAge = apparentAge
+	if correctedAgeValue > correctedInitialAge {
+		correctedInitialAge = correctedAgeValue
+	}
+
+	return correctedInitialAge + time.Since(responseTime)
+}
+
+// SetAge sets resp's Age header to its currently computed age, per
+// https://tools.ietf.org/html/rfc7234#section-5.1.
+func setAge(resp *http.Response) {
+	resp.Header.Set("Age", strconv.Itoa(int(age(resp).Seconds())))
+}
+
+// Timestamp parses the named header as a timestamp set by
+// `setTimestamps`.
+func timestamp(h http.Header, name string) (time.Time, bool) {
+	var v = h.Get(name)
+	if v == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	return t, err == nil
+}
@@ -0,0 +1,52 @@
+package httpcache
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAge(t *testing.T) {
+	t.Run("falls back to time since date without recorded timestamps", func(t *testing.T) {
+		var assert = require.New(t)
+		var past = time.Now().Add(-time.Hour).UTC()
+
+		var resp = &http.Response{Header: http.Header{
+			"Date": []string{past.Format(time.RFC1123)},
+		}}
+
+		assert.InDelta(time.Hour.Seconds(), age(resp).Seconds(), 2)
+	})
+
+	t.Run("accounts for the Age header and response delay", func(t *testing.T) {
+		var assert = require.New(t)
+		var (
+			date         = time.Now().Add(-time.Minute).UTC()
+			requestTime  = date.Add(2 * time.Second)
+			responseTime = requestTime.Add(3 * time.Second)
+		)
+
+		var resp = &http.Response{Header: http.Header{
+			"Date": []string{date.Format(time.RFC1123)},
+			"Age":  []string{"30"},
+		}}
+		setTimestamps(resp, requestTime, responseTime)
+
+		var want = 30*time.Second + 3*time.Second + time.Since(responseTime)
+		assert.InDelta(want.Seconds(), age(resp).Seconds(), 2)
+	})
+
+	t.Run("setAge writes the computed age back", func(t *testing.T) {
+		var assert = require.New(t)
+		var past = time.Now().Add(-90 * time.Second).UTC()
+
+		var resp = &http.Response{Header: http.Header{
+			"Date": []string{past.Format(time.RFC1123)},
+		}}
+
+		setAge(resp)
+		assert.Equal("90", resp.Header.Get("Age"))
+	})
+}
@@ -15,6 +15,11 @@ type Aggressive struct {
 	//
 	// If <= 0, defaults to 1 day.
 	Lifetime time.Duration
+
+	// Revalidate, when true, makes expired responses that carry a
+	// validator (ETag or Last-Modified) go through conditional
+	// revalidation instead of being transparently re-fetched.
+	Revalidate bool
 }
 
 // Cache implementation.
@@ -49,18 +54,26 @@ func (a Aggressive) store(resp *http.Response) bool {
 
 // Fresh implementation.
 func (a Aggressive) fresh(resp *http.Response) Freshness {
-	if date, ok := date(resp.Header); ok {
-		if time.Since(date) < a.lifetime() {
+	if _, ok := date(resp.Header); ok {
+		if age(resp) < a.configuredLifetime() {
 			return Fresh
 		}
 	}
+	if a.Revalidate && validators(resp.Header) {
+		return MustRevalidate
+	}
 	return Transparent
 }
 
-// Lifetime returns the lifetime.
-func (a Aggressive) lifetime() time.Duration {
+// ConfiguredLifetime returns the configured lifetime.
+func (a Aggressive) configuredLifetime() time.Duration {
 	if a.Lifetime > 0 {
 		return a.Lifetime
 	}
 	return 24 * time.Hour
 }
+
+// Lifetime implementation.
+func (a Aggressive) lifetime(resp *http.Response) (time.Duration, bool) {
+	return a.configuredLifetime(), true
+}
@@ -123,4 +123,28 @@ func TestAggressive(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("revalidate", func(t *testing.T) {
+		var resp = &http.Response{
+			Request: &http.Request{},
+			Header: http.Header{
+				"Date": []string{time.Now().Add(-(48 * time.Hour)).Format(time.RFC1123)},
+				"Etag": []string{`"v1"`},
+			},
+		}
+
+		t.Run("transparent by default", func(t *testing.T) {
+			var assert = require.New(t)
+			var strategy = Aggressive{}
+
+			assert.Equal(Transparent, strategy.fresh(resp))
+		})
+
+		t.Run("must-revalidate when opted in with a validator", func(t *testing.T) {
+			var assert = require.New(t)
+			var strategy = Aggressive{Revalidate: true}
+
+			assert.Equal(MustRevalidate, strategy.fresh(resp))
+		})
+	})
 }
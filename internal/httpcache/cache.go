@@ -0,0 +1,295 @@
+package httpcache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"time"
+
+	"github.com/yields/ant/internal/httpcache/cachecontrol"
+)
+
+// Do performs the given request, consulting the cache first.
+//
+// If the request is not cacheable, or nothing is stored for it, the
+// request is forwarded to the underlying client as-is. A fresh cached
+// response is returned without touching the network, a response within
+// its "stale-while-revalidate" window is returned immediately while a
+// revalidation happens in the background, a response that requires
+// revalidation is conditionally re-requested with If-None-Match /
+// If-Modified-Since, and anything else is re-fetched.
+func (c *Cache) Do(req *http.Request) (*http.Response, error) {
+	if unsafeMethod(req.Method) {
+		return c.invalidate(req)
+	}
+
+	if !c.strategy.cache(req) {
+		return c.client.Do(req)
+	}
+
+	var (
+		ctx = req.Context()
+		key = requestKey(req)
+	)
+
+	raw, err := c.storage.Load(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("httpcache: load %d - %w", key, err)
+	}
+
+	if raw == nil {
+		return c.fetchCoalesced(req, key)
+	}
+
+	cached, err := decodeResponse(raw, req)
+	if err != nil {
+		return nil, fmt.Errorf("httpcache: decode %d - %w", key, err)
+	}
+
+	switch c.strategy.fresh(cached) {
+	case Fresh:
+		setAge(cached)
+		stripTimestamps(cached)
+		return cached, nil
+	case MustRevalidate:
+		return c.revalidate(req, key, cached)
+	case StaleWhileRevalidate:
+		return c.serveStaleWhileRevalidating(req, key, cached)
+	default:
+		return c.fetchCoalesced(req, key)
+	}
+}
+
+// ServeStaleWhileRevalidating returns cached immediately, kicking off a
+// detached revalidation of key against the origin in the background,
+// per https://tools.ietf.org/html/rfc5861#section-3.
+func (c *Cache) serveStaleWhileRevalidating(req *http.Request, key uint64, cached *http.Response) (*http.Response, error) {
+	body, err := io.ReadAll(cached.Body)
+	cached.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("httpcache: read body - %w", err)
+	}
+
+	var background = cloneResponse(cached, body)
+	cached.Body = io.NopCloser(bytes.NewReader(body))
+
+	setAge(cached)
+	stripTimestamps(cached)
+
+	go func() {
+		var breq = req.Clone(context.Background())
+		c.revalidate(breq, key, background)
+	}()
+
+	return cached, nil
+}
+
+// FetchCoalesced fetches req, coalescing concurrent calls for the same
+// key into a single upstream request when the cache was built with
+// `WithSingleflight(true)`.
+func (c *Cache) fetchCoalesced(req *http.Request, key uint64) (*http.Response, error) {
+	if c.sf == nil {
+		return c.fetch(req, key)
+	}
+
+	return c.sf.do(key,
+		func() (*http.Response, error) {
+			return c.fetch(req, key)
+		},
+		func() (*http.Response, error) {
+			raw, err := c.storage.Load(req.Context(), key)
+			if err != nil || raw == nil {
+				return nil, err
+			}
+			resp, err := decodeResponse(raw, req)
+			if err != nil {
+				return nil, err
+			}
+			setAge(resp)
+			stripTimestamps(resp)
+			return resp, nil
+		},
+	)
+}
+
+// Invalidate performs an unsafe request, purging any cached GET/HEAD
+// entry for its URL once it succeeds, per
+// https://tools.ietf.org/html/rfc7234#section-4.4.
+func (c *Cache) invalidate(req *http.Request) (*http.Response, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 400 {
+		for _, method := range []string{"GET", "HEAD"} {
+			var purge = &http.Request{Method: method, URL: req.URL}
+			c.storage.Delete(req.Context(), requestKey(purge))
+		}
+	}
+
+	return resp, nil
+}
+
+// UnsafeMethod returns true if method is not safe per
+// https://tools.ietf.org/html/rfc7231#section-4.2.1.
+func unsafeMethod(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "TRACE":
+		return false
+	default:
+		return true
+	}
+}
+
+// Fetch performs req against the client and stores the response if
+// the strategy allows it.
+func (c *Cache) fetch(req *http.Request, key uint64) (*http.Response, error) {
+	var requestTime = time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	return c.store(req, key, resp, requestTime, time.Now())
+}
+
+// Store buffers resp's body, so it can be both dumped to storage and
+// handed back to the caller, and stores it - along with the
+// requestTime/responseTime round-trip, used by `age` - if the
+// strategy allows it, purging any existing entry under key otherwise.
+func (c *Cache) store(req *http.Request, key uint64, resp *http.Response, requestTime, responseTime time.Time) (*http.Response, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("httpcache: read body - %w", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if c.strategy.store(resp) {
+		setTimestamps(resp, requestTime, responseTime)
+		if raw, err := encodeResponse(resp); err == nil {
+			c.storage.Store(req.Context(), key, raw)
+		}
+		stripTimestamps(resp)
+	} else {
+		// The strategy declined to store this response - e.g. a
+		// revalidation now reports "no-store" - so any previously
+		// stored entry under key would otherwise be orphaned forever.
+		c.storage.Delete(req.Context(), key)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// Revalidate conditionally re-requests cached against the origin using
+// the ETag / Last-Modified validators carried by its headers.
+//
+// On a 304 Not Modified reply the cached headers are updated in place
+// and the cached body is returned to the caller; any other reply is
+// treated as a fresh response and stored as usual.
+func (c *Cache) revalidate(req *http.Request, key uint64, cached *http.Response) (*http.Response, error) {
+	var creq = req.Clone(req.Context())
+
+	if etag, ok := etag(cached.Header); ok {
+		creq.Header.Set("If-None-Match", etag)
+	}
+	if lm, ok := lastModified(cached.Header); ok {
+		creq.Header.Set("If-Modified-Since", lm.Format(time.RFC1123))
+	}
+
+	var requestTime = time.Now()
+	resp, err := c.client.Do(creq)
+	if err != nil {
+		var cc = cachecontrol.Parse(cached.Header)
+		if !cc.MustRevalidate() {
+			if d, ok := cc.StaleIfError(); ok {
+				if life, ok := c.strategy.lifetime(cached); ok && age(cached)-life <= d {
+					setAge(cached)
+					stripTimestamps(cached)
+					return cached, nil
+				}
+			}
+		}
+		return nil, err
+	}
+	var responseTime = time.Now()
+
+	if resp.StatusCode != http.StatusNotModified {
+		return c.store(req, key, resp, requestTime, responseTime)
+	}
+	defer resp.Body.Close()
+
+	mergeHeaders(cached, resp)
+	setTimestamps(cached, requestTime, responseTime)
+
+	body, err := io.ReadAll(cached.Body)
+	cached.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("httpcache: read body - %w", err)
+	}
+	cached.Body = io.NopCloser(bytes.NewReader(body))
+
+	if raw, err := encodeResponse(cached); err == nil {
+		c.storage.Store(req.Context(), key, raw)
+	}
+
+	setAge(cached)
+	stripTimestamps(cached)
+	cached.Body = io.NopCloser(bytes.NewReader(body))
+	return cached, nil
+}
+
+// MergeHeaders updates stored's headers in place with the values
+// carried by a 304 Not Modified reply, per
+// https://tools.ietf.org/html/rfc7234#section-4.3.3.
+func mergeHeaders(stored, resp *http.Response) {
+	for _, h := range []string{"Date", "Expires", "Cache-Control", "Age"} {
+		if v := resp.Header.Get(h); v != "" {
+			stored.Header.Set(h, v)
+		}
+	}
+
+	// A validated response is no longer subject to the "Heuristic
+	// Expiration" warning, so any 1xx warning is dropped.
+	stored.Header.Del("Warning")
+	if w := resp.Header.Get("Warning"); w != "" {
+		stored.Header.Set("Warning", w)
+	}
+}
+
+// RequestKey returns the cache key for req.
+//
+// Vary handling happens after the load, by comparing the matched
+// request's headers against the ones captured on the stored response,
+// see `matches`.
+func requestKey(req *http.Request) uint64 {
+	var h = fnv.New64a()
+	io.WriteString(h, req.Method)
+	io.WriteString(h, "|")
+	io.WriteString(h, req.URL.String())
+	return h.Sum64()
+}
+
+// EncodeResponse dumps resp, including its body, into a byteslice
+// suitable for `Storage.Store`.
+func encodeResponse(resp *http.Response) ([]byte, error) {
+	return httputil.DumpResponse(resp, true)
+}
+
+// DecodeResponse parses raw back into a response, as stored by
+// `encodeResponse`, associating it with req.
+func decodeResponse(raw []byte, req *http.Request) (*http.Response, error) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(raw)), req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Request = req
+	return resp, nil
+}
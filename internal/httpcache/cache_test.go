@@ -0,0 +1,329 @@
+package httpcache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// roundTripperFunc adapts a function to a Client.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newRequest(t *testing.T) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest("GET", "http://example.com/", nil)
+	require.NoError(t, err)
+	return req
+}
+
+func TestCacheDo(t *testing.T) {
+	t.Run("fetches from the client on miss", func(t *testing.T) {
+		var assert = require.New(t)
+		var storage = &Memstore{}
+		var calls int
+
+		var cache, err = NewCache(
+			WithStorage(storage),
+			WithClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				calls++
+				var rec = httptest.NewRecorder()
+				rec.WriteHeader(204)
+				var resp = rec.Result()
+				resp.Request = req
+				return resp, nil
+			})),
+		)
+		assert.NoError(err)
+
+		var req = newRequest(t)
+		req.Header = http.Header{}
+
+		resp, err := cache.Do(req)
+		assert.NoError(err)
+		assert.Equal(204, resp.StatusCode)
+		assert.Equal(1, calls)
+	})
+
+	t.Run("returns fresh response without hitting the client", func(t *testing.T) {
+		var assert = require.New(t)
+		var storage = &Memstore{}
+		var now = time.Now().UTC()
+
+		var rec = httptest.NewRecorder()
+		rec.Header().Set("Cache-Control", "max-age=60")
+		rec.Header().Set("Date", now.Format(time.RFC1123))
+		rec.WriteHeader(200)
+		rec.Body = bytes.NewBufferString("hello")
+
+		var stored = rec.Result()
+		setTimestamps(stored, now, now)
+
+		raw, err := encodeResponse(stored)
+		assert.NoError(err)
+		assert.NoError(storage.Store(context.Background(), requestKey(newRequest(t)), raw))
+
+		var cache, _ = NewCache(
+			WithStorage(storage),
+			WithClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				t.Fatal("client should not be called for a fresh response")
+				return nil, nil
+			})),
+		)
+
+		var req = newRequest(t)
+		req.Header = http.Header{}
+
+		resp, err := cache.Do(req)
+		assert.NoError(err)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal("hello", string(body))
+		assert.Empty(resp.Header.Get(headerRequestTime))
+		assert.Empty(resp.Header.Get(headerResponseTime))
+	})
+
+	t.Run("revalidates a stale response with a validator", func(t *testing.T) {
+		var assert = require.New(t)
+		var storage = &Memstore{}
+		var past = time.Now().Add(-time.Hour).UTC()
+
+		var rec = httptest.NewRecorder()
+		rec.Header().Set("Cache-Control", "max-age=1")
+		rec.Header().Set("Date", past.Format(time.RFC1123))
+		rec.Header().Set("Etag", `"v1"`)
+		rec.WriteHeader(200)
+		rec.Body = bytes.NewBufferString("stale body")
+
+		raw, err := encodeResponse(rec.Result())
+		assert.NoError(err)
+		assert.NoError(storage.Store(context.Background(), requestKey(newRequest(t)), raw))
+
+		var cache, _ = NewCache(
+			WithStorage(storage),
+			WithClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				assert.Equal(`"v1"`, req.Header.Get("If-None-Match"))
+				return &http.Response{
+					StatusCode: http.StatusNotModified,
+					Header: http.Header{
+						"Date": []string{time.Now().UTC().Format(time.RFC1123)},
+					},
+					Body: io.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			})),
+		)
+
+		var req = newRequest(t)
+		req.Header = http.Header{}
+
+		resp, err := cache.Do(req)
+		assert.NoError(err)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal("stale body", string(body))
+	})
+
+	t.Run("purges the stale entry when a revalidation now declines to store", func(t *testing.T) {
+		var assert = require.New(t)
+		var storage = &Memstore{}
+		var past = time.Now().Add(-time.Hour).UTC()
+		var key = requestKey(newRequest(t))
+
+		var rec = httptest.NewRecorder()
+		rec.Header().Set("Cache-Control", "max-age=1")
+		rec.Header().Set("Date", past.Format(time.RFC1123))
+		rec.Header().Set("Etag", `"v1"`)
+		rec.WriteHeader(200)
+		rec.Body = bytes.NewBufferString("stale body")
+
+		raw, err := encodeResponse(rec.Result())
+		assert.NoError(err)
+		assert.NoError(storage.Store(context.Background(), key, raw))
+
+		var cache, _ = NewCache(
+			WithStorage(storage),
+			WithClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				assert.Equal(`"v1"`, req.Header.Get("If-None-Match"))
+				var rec = httptest.NewRecorder()
+				rec.Header().Set("Cache-Control", "no-store")
+				rec.WriteHeader(200)
+				rec.Body = bytes.NewBufferString("fresh body")
+				var resp = rec.Result()
+				resp.Request = req
+				return resp, nil
+			})),
+		)
+
+		var req = newRequest(t)
+		req.Header = http.Header{}
+
+		resp, err := cache.Do(req)
+		assert.NoError(err)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal("fresh body", string(body))
+
+		remaining, err := storage.Load(context.Background(), key)
+		assert.NoError(err)
+		assert.Nil(remaining)
+	})
+
+	t.Run("serves stale-if-error without leaking bookkeeping headers", func(t *testing.T) {
+		var assert = require.New(t)
+		var storage = &Memstore{}
+		var past = time.Now().Add(-time.Hour).UTC()
+
+		var rec = httptest.NewRecorder()
+		rec.Header().Set("Cache-Control", "max-age=1, stale-if-error=3600")
+		rec.Header().Set("Date", past.Format(time.RFC1123))
+		rec.Header().Set("Etag", `"v1"`)
+		rec.WriteHeader(200)
+		rec.Body = bytes.NewBufferString("stale body")
+
+		var stored = rec.Result()
+		setTimestamps(stored, past, past)
+
+		raw, err := encodeResponse(stored)
+		assert.NoError(err)
+		assert.NoError(storage.Store(context.Background(), requestKey(newRequest(t)), raw))
+
+		var cache, _ = NewCache(
+			WithStorage(storage),
+			WithClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return nil, errors.New("origin unreachable")
+			})),
+		)
+
+		var req = newRequest(t)
+		req.Header = http.Header{}
+
+		resp, err := cache.Do(req)
+		assert.NoError(err)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal("stale body", string(body))
+		assert.Empty(resp.Header.Get(headerRequestTime))
+		assert.Empty(resp.Header.Get(headerResponseTime))
+	})
+
+	t.Run("serves stale-while-revalidate immediately, revalidating in the background", func(t *testing.T) {
+		var assert = require.New(t)
+		var storage = &Memstore{}
+		var past = time.Now().Add(-time.Minute).UTC()
+		var revalidated = make(chan struct{})
+
+		var rec = httptest.NewRecorder()
+		rec.Header().Set("Cache-Control", "max-age=1, stale-while-revalidate=60")
+		rec.Header().Set("Date", past.Format(time.RFC1123))
+		rec.Header().Set("Etag", `"v1"`)
+		rec.WriteHeader(200)
+		rec.Body = bytes.NewBufferString("stale body")
+
+		raw, err := encodeResponse(rec.Result())
+		assert.NoError(err)
+		assert.NoError(storage.Store(context.Background(), requestKey(newRequest(t)), raw))
+
+		var cache, _ = NewCache(
+			WithStorage(storage),
+			WithClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				assert.Equal(`"v1"`, req.Header.Get("If-None-Match"))
+				close(revalidated)
+				return &http.Response{
+					StatusCode: http.StatusNotModified,
+					Header: http.Header{
+						"Date": []string{time.Now().UTC().Format(time.RFC1123)},
+					},
+					Body: io.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			})),
+		)
+
+		var req = newRequest(t)
+		req.Header = http.Header{}
+
+		resp, err := cache.Do(req)
+		assert.NoError(err)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal("stale body", string(body))
+
+		select {
+		case <-revalidated:
+		case <-time.After(time.Second):
+			t.Fatal("background revalidation never happened")
+		}
+	})
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	t.Run("purges cached GET and HEAD entries on a successful unsafe request", func(t *testing.T) {
+		var assert = require.New(t)
+		var storage = &Memstore{}
+		var ctx = context.Background()
+
+		var getReq, err = http.NewRequest("GET", "http://example.com/", nil)
+		assert.NoError(err)
+		var headReq, _ = http.NewRequest("HEAD", "http://example.com/", nil)
+
+		assert.NoError(storage.Store(ctx, requestKey(getReq), []byte("get response")))
+		assert.NoError(storage.Store(ctx, requestKey(headReq), []byte("head response")))
+
+		var cache, _ = NewCache(
+			WithStorage(storage),
+			WithClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				var rec = httptest.NewRecorder()
+				rec.WriteHeader(204)
+				var resp = rec.Result()
+				resp.Request = req
+				return resp, nil
+			})),
+		)
+
+		postReq, _ := http.NewRequest("POST", "http://example.com/", nil)
+		resp, err := cache.Do(postReq)
+		assert.NoError(err)
+		assert.Equal(204, resp.StatusCode)
+
+		getRaw, err := storage.Load(ctx, requestKey(getReq))
+		assert.NoError(err)
+		assert.Nil(getRaw)
+
+		headRaw, err := storage.Load(ctx, requestKey(headReq))
+		assert.NoError(err)
+		assert.Nil(headRaw)
+	})
+
+	t.Run("leaves the cache alone when the unsafe request fails", func(t *testing.T) {
+		var assert = require.New(t)
+		var storage = &Memstore{}
+		var ctx = context.Background()
+
+		var getReq, err = http.NewRequest("GET", "http://example.com/", nil)
+		assert.NoError(err)
+		assert.NoError(storage.Store(ctx, requestKey(getReq), []byte("get response")))
+
+		var cache, _ = NewCache(
+			WithStorage(storage),
+			WithClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				var rec = httptest.NewRecorder()
+				rec.WriteHeader(500)
+				var resp = rec.Result()
+				resp.Request = req
+				return resp, nil
+			})),
+		)
+
+		postReq, _ := http.NewRequest("POST", "http://example.com/", nil)
+		_, err = cache.Do(postReq)
+		assert.NoError(err)
+
+		getRaw, err := storage.Load(ctx, requestKey(getReq))
+		assert.NoError(err)
+		assert.Equal([]byte("get response"), getRaw)
+	})
+}
@@ -0,0 +1,182 @@
+// Package cachecontrol parses the HTTP Cache-Control header.
+//
+// https://tools.ietf.org/html/rfc7234#section-5.2
+package cachecontrol
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Directives represents a parsed Cache-Control header.
+//
+// Directive names are canonicalized to lower-case; values are
+// unquoted but otherwise verbatim. A directive with no `=value` is
+// stored with an empty value - use `Has` to tell it apart from a
+// directive that is simply absent.
+type Directives map[string]string
+
+// Parse parses the Cache-Control header(s) of h.
+//
+// Multiple Cache-Control header lines, as well as multiple
+// comma-separated directives on a single line, are all merged into
+// one Directives value.
+func Parse(h http.Header) Directives {
+	var d = make(Directives)
+
+	for _, line := range h.Values("Cache-Control") {
+		for _, tok := range tokenize(line) {
+			if tok.name == "" {
+				continue
+			}
+			d[tok.name] = tok.value
+		}
+	}
+
+	return d
+}
+
+// Has returns true if the named directive is present.
+func (d Directives) Has(name string) bool {
+	_, ok := d[name]
+	return ok
+}
+
+// Get returns the raw value of the named directive.
+func (d Directives) Get(name string) (string, bool) {
+	v, ok := d[name]
+	return v, ok
+}
+
+// NoStore returns true if the "no-store" directive is present.
+func (d Directives) NoStore() bool {
+	return d.Has("no-store")
+}
+
+// NoCache returns the "no-cache" directive.
+//
+// Fields is the optional list of header field names the directive is
+// scoped to (e.g. `no-cache="set-cookie"`); it is empty when the
+// directive applies to the whole response.
+func (d Directives) NoCache() (fields []string, present bool) {
+	v, ok := d["no-cache"]
+	if !ok {
+		return nil, false
+	}
+	return splitFields(v), true
+}
+
+// Private returns the "private" directive, and its scoped fields if
+// any, the same way `NoCache` does.
+func (d Directives) Private() (fields []string, present bool) {
+	v, ok := d["private"]
+	if !ok {
+		return nil, false
+	}
+	return splitFields(v), true
+}
+
+// Public returns true if the "public" directive is present.
+func (d Directives) Public() bool {
+	return d.Has("public")
+}
+
+// MustRevalidate returns true if "must-revalidate" is present.
+func (d Directives) MustRevalidate() bool {
+	return d.Has("must-revalidate")
+}
+
+// ProxyRevalidate returns true if "proxy-revalidate" is present.
+func (d Directives) ProxyRevalidate() bool {
+	return d.Has("proxy-revalidate")
+}
+
+// Immutable returns true if "immutable" is present.
+func (d Directives) Immutable() bool {
+	return d.Has("immutable")
+}
+
+// OnlyIfCached returns true if "only-if-cached" is present.
+func (d Directives) OnlyIfCached() bool {
+	return d.Has("only-if-cached")
+}
+
+// MaxAge returns the "max-age" directive.
+func (d Directives) MaxAge() (time.Duration, bool) {
+	return d.seconds("max-age")
+}
+
+// SMaxAge returns the "s-maxage" directive.
+func (d Directives) SMaxAge() (time.Duration, bool) {
+	return d.seconds("s-maxage")
+}
+
+// MinFresh returns the "min-fresh" directive.
+func (d Directives) MinFresh() (time.Duration, bool) {
+	return d.seconds("min-fresh")
+}
+
+// MaxStale returns the "max-stale" directive.
+//
+// The directive may appear without a value, meaning the client will
+// accept a response of any staleness; in that case unlimited is true.
+func (d Directives) MaxStale() (age time.Duration, unlimited, ok bool) {
+	v, present := d["max-stale"]
+	if !present {
+		return 0, false, false
+	}
+	if v == "" {
+		return 0, true, true
+	}
+	age, ok = parseSeconds(v)
+	return age, false, ok
+}
+
+// StaleWhileRevalidate returns the "stale-while-revalidate" directive.
+//
+// https://tools.ietf.org/html/rfc5861#section-3
+func (d Directives) StaleWhileRevalidate() (time.Duration, bool) {
+	return d.seconds("stale-while-revalidate")
+}
+
+// StaleIfError returns the "stale-if-error" directive.
+//
+// https://tools.ietf.org/html/rfc5861#section-4
+func (d Directives) StaleIfError() (time.Duration, bool) {
+	return d.seconds("stale-if-error")
+}
+
+// Seconds returns the named directive's value, parsed as a duration
+// in seconds.
+func (d Directives) seconds(name string) (time.Duration, bool) {
+	v, ok := d[name]
+	if !ok {
+		return 0, false
+	}
+	return parseSeconds(v)
+}
+
+// ParseSeconds parses v as a non-negative integer number of seconds.
+func parseSeconds(v string) (time.Duration, bool) {
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// SplitFields splits the comma-separated field-name list carried by
+// the value of a "no-cache" or "private" directive.
+func splitFields(v string) []string {
+	var fields []string
+
+	for _, f := range strings.Split(v, ",") {
+		if f = strings.ToLower(strings.TrimSpace(f)); f != "" {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
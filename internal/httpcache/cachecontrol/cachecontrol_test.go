@@ -0,0 +1,81 @@
+package cachecontrol
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("max-age", func(t *testing.T) {
+		var assert = require.New(t)
+		var d = Parse(http.Header{"Cache-Control": {"max-age=5"}})
+
+		age, ok := d.MaxAge()
+		assert.True(ok)
+		assert.Equal(5*time.Second, age)
+	})
+
+	t.Run("quoted private fields", func(t *testing.T) {
+		var assert = require.New(t)
+		var d = Parse(http.Header{"Cache-Control": {`private="Set-Cookie, X-Foo", max-age=60`}})
+
+		fields, ok := d.Private()
+		assert.True(ok)
+		assert.Equal([]string{"set-cookie", "x-foo"}, fields)
+
+		age, ok := d.MaxAge()
+		assert.True(ok)
+		assert.Equal(60*time.Second, age)
+	})
+
+	t.Run("multi-value header", func(t *testing.T) {
+		var assert = require.New(t)
+		var d = Parse(http.Header{"Cache-Control": {"no-cache", "max-age=30"}})
+
+		assert.True(d.Has("no-cache"))
+		age, ok := d.MaxAge()
+		assert.True(ok)
+		assert.Equal(30*time.Second, age)
+	})
+
+	t.Run("max-stale without value is unlimited", func(t *testing.T) {
+		var assert = require.New(t)
+		var d = Parse(http.Header{"Cache-Control": {"max-stale"}})
+
+		age, unlimited, ok := d.MaxStale()
+		assert.True(ok)
+		assert.True(unlimited)
+		assert.Zero(age)
+	})
+
+	t.Run("max-stale with value", func(t *testing.T) {
+		var assert = require.New(t)
+		var d = Parse(http.Header{"Cache-Control": {"max-stale=10"}})
+
+		age, unlimited, ok := d.MaxStale()
+		assert.True(ok)
+		assert.False(unlimited)
+		assert.Equal(10*time.Second, age)
+	})
+
+	t.Run("no directives", func(t *testing.T) {
+		var assert = require.New(t)
+		var d = Parse(http.Header{})
+
+		assert.False(d.Has("no-store"))
+		_, ok := d.MaxAge()
+		assert.False(ok)
+	})
+
+	t.Run("extension directive does not break parsing", func(t *testing.T) {
+		var assert = require.New(t)
+		var d = Parse(http.Header{"Cache-Control": {"community=UCI, max-age=5"}})
+
+		age, ok := d.MaxAge()
+		assert.True(ok)
+		assert.Equal(5*time.Second, age)
+	})
+}
@@ -0,0 +1,85 @@
+package cachecontrol
+
+import "strings"
+
+// Token is a single `name` or `name=value` directive.
+type token struct {
+	name  string
+	value string
+}
+
+// Tokenize splits a Cache-Control header value into directives.
+//
+// Commas and `=` signs inside a quoted-string value are not treated
+// as delimiters, and backslash-escaped characters within one are
+// unescaped, per the quoted-string grammar of RFC 7230 section 3.2.6.
+func tokenize(s string) []token {
+	var toks []token
+	var i int
+
+	for i < len(s) {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == ',') {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+
+		var start = i
+		for i < len(s) && s[i] != '=' && s[i] != ',' {
+			i++
+		}
+		var name = strings.ToLower(strings.TrimSpace(s[start:i]))
+
+		var value string
+		if i < len(s) && s[i] == '=' {
+			i++
+			for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+				i++
+			}
+
+			if i < len(s) && s[i] == '"' {
+				value, i = readQuoted(s, i)
+			} else {
+				var vstart = i
+				for i < len(s) && s[i] != ',' {
+					i++
+				}
+				value = strings.TrimSpace(s[vstart:i])
+			}
+		}
+
+		if name != "" {
+			toks = append(toks, token{name: name, value: value})
+		}
+	}
+
+	return toks
+}
+
+// ReadQuoted reads a quoted-string starting at s[i], which must hold
+// the opening `"`, returning its unescaped contents and the index
+// just past the closing quote.
+func readQuoted(s string, i int) (string, int) {
+	var b strings.Builder
+	i++
+
+	for i < len(s) {
+		switch c := s[i]; c {
+		case '\\':
+			if i+1 < len(s) {
+				b.WriteByte(s[i+1])
+				i += 2
+				continue
+			}
+			i++
+		case '"':
+			return b.String(), i + 1
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+
+	return b.String(), i
+}
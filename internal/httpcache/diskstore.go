@@ -0,0 +1,163 @@
+package httpcache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DiskStore implements an on-disk Storage backend.
+//
+// Each entry is written to a content-addressed path, sharded into
+// subdirectories by the first byte of its key, with an atomic
+// rename-on-write so a reader never observes a partially-written file.
+// A JSON sidecar file next to the entry holds its metadata (size, last
+// access). Access to an entry is serialized by one of 256 locks,
+// picked by the last byte of its key, so only keys that share that
+// byte contend.
+type DiskStore struct {
+	// Dir is the root directory entries are stored under.
+	//
+	// It is created, along with any shard subdirectories, as needed.
+	Dir string
+
+	shards [256]sync.Mutex
+	hits   uint64
+	misses uint64
+	bytes  int64
+}
+
+// NewDiskStore returns a store rooted at dir.
+func NewDiskStore(dir string) *DiskStore {
+	return &DiskStore{Dir: dir}
+}
+
+// diskMeta is the sidecar metadata kept next to a stored entry.
+type diskMeta struct {
+	Size       int64     `json:"size"`
+	LastAccess time.Time `json:"last_access"`
+}
+
+// Store implementation.
+func (d *DiskStore) Store(ctx context.Context, key uint64, value []byte) error {
+	var shard = &d.shards[byte(key)]
+	shard.Lock()
+	defer shard.Unlock()
+
+	var data, meta = d.paths(key)
+
+	var prevSize int64
+	if fi, err := os.Stat(data); err == nil {
+		prevSize = fi.Size()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(data), 0o755); err != nil {
+		return fmt.Errorf("httpcache: mkdir %q - %w", filepath.Dir(data), err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(data), "."+filepath.Base(data)+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("httpcache: create temp file - %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+		return fmt.Errorf("httpcache: write %q - %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("httpcache: close %q - %w", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), data); err != nil {
+		return fmt.Errorf("httpcache: rename %q - %w", data, err)
+	}
+
+	atomic.AddInt64(&d.bytes, int64(len(value))-prevSize)
+
+	var m, _ = json.Marshal(diskMeta{Size: int64(len(value)), LastAccess: time.Now()})
+	if err := os.WriteFile(meta, m, 0o644); err != nil {
+		return fmt.Errorf("httpcache: write %q - %w", meta, err)
+	}
+
+	return nil
+}
+
+// Load implementation.
+func (d *DiskStore) Load(ctx context.Context, key uint64) ([]byte, error) {
+	var shard = &d.shards[byte(key)]
+	shard.Lock()
+	defer shard.Unlock()
+
+	var data, meta = d.paths(key)
+
+	value, err := os.ReadFile(data)
+	if err != nil {
+		if os.IsNotExist(err) {
+			atomic.AddUint64(&d.misses, 1)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("httpcache: read %q - %w", data, err)
+	}
+
+	atomic.AddUint64(&d.hits, 1)
+	d.touch(meta, int64(len(value)))
+
+	return value, nil
+}
+
+// Delete implementation.
+func (d *DiskStore) Delete(ctx context.Context, key uint64) error {
+	var shard = &d.shards[byte(key)]
+	shard.Lock()
+	defer shard.Unlock()
+
+	var data, meta = d.paths(key)
+
+	if fi, err := os.Stat(data); err == nil {
+		atomic.AddInt64(&d.bytes, -fi.Size())
+	}
+
+	if err := os.Remove(data); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("httpcache: remove %q - %w", data, err)
+	}
+	os.Remove(meta)
+
+	return nil
+}
+
+// Stats returns the store's hit/miss/byte counters.
+//
+// Evictions is always zero, as DiskStore has no size budget of its
+// own - wrap it with LRUStore for bounded size.
+//
+// Bytes is the size of the values this DiskStore has written, less
+// the size of those it has deleted; Dir is not scanned, so entries
+// already on disk when the store was created are not counted.
+func (d *DiskStore) Stats() Stats {
+	return Stats{
+		Hits:   atomic.LoadUint64(&d.hits),
+		Misses: atomic.LoadUint64(&d.misses),
+		Bytes:  atomic.LoadInt64(&d.bytes),
+	}
+}
+
+// Touch updates the last-access time recorded in the sidecar file at
+// meta. Failures are ignored, as this is best-effort bookkeeping.
+func (d *DiskStore) touch(meta string, size int64) {
+	var m = diskMeta{Size: size, LastAccess: time.Now()}
+	if raw, err := json.Marshal(m); err == nil {
+		os.WriteFile(meta, raw, 0o644)
+	}
+}
+
+// Paths returns the data and sidecar metadata paths for key.
+func (d *DiskStore) paths(key uint64) (data, meta string) {
+	var name = fmt.Sprintf("%016x", key)
+	var dir = filepath.Join(d.Dir, name[:2])
+	return filepath.Join(dir, name), filepath.Join(dir, name+".meta")
+}
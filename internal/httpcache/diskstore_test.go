@@ -0,0 +1,94 @@
+package httpcache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskStore(t *testing.T) {
+	t.Run("store and load", func(t *testing.T) {
+		var assert = require.New(t)
+		var store = NewDiskStore(t.TempDir())
+		var ctx = context.Background()
+
+		assert.NoError(store.Store(ctx, 1, []byte("hello")))
+
+		v, err := store.Load(ctx, 1)
+		assert.NoError(err)
+		assert.Equal([]byte("hello"), v)
+	})
+
+	t.Run("load missing returns nil, nil", func(t *testing.T) {
+		var assert = require.New(t)
+		var store = NewDiskStore(t.TempDir())
+
+		v, err := store.Load(context.Background(), 42)
+		assert.NoError(err)
+		assert.Nil(v)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		var assert = require.New(t)
+		var store = NewDiskStore(t.TempDir())
+		var ctx = context.Background()
+
+		assert.NoError(store.Store(ctx, 1, []byte("hello")))
+		assert.NoError(store.Delete(ctx, 1))
+
+		v, err := store.Load(ctx, 1)
+		assert.NoError(err)
+		assert.Nil(v)
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		var assert = require.New(t)
+		var store = NewDiskStore(t.TempDir())
+		var ctx = context.Background()
+
+		assert.NoError(store.Store(ctx, 1, []byte("hello")))
+		store.Load(ctx, 1)
+		store.Load(ctx, 2)
+
+		var stats = store.Stats()
+		assert.Equal(uint64(1), stats.Hits)
+		assert.Equal(uint64(1), stats.Misses)
+		assert.Equal(int64(5), stats.Bytes)
+	})
+
+	t.Run("overwrite updates size", func(t *testing.T) {
+		var assert = require.New(t)
+		var store = NewDiskStore(t.TempDir())
+		var ctx = context.Background()
+
+		assert.NoError(store.Store(ctx, 1, []byte("hello")))
+		assert.NoError(store.Store(ctx, 1, []byte("hi")))
+
+		assert.Equal(int64(2), store.Stats().Bytes)
+	})
+}
+
+func BenchmarkDiskStore_Store(b *testing.B) {
+	var store = NewDiskStore(b.TempDir())
+	var ctx = context.Background()
+	var value = []byte("the quick brown fox jumps over the lazy dog")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		store.Store(ctx, uint64(i), value)
+	}
+}
+
+func BenchmarkDiskStore_Load(b *testing.B) {
+	var store = NewDiskStore(b.TempDir())
+	var ctx = context.Background()
+	var value = []byte("the quick brown fox jumps over the lazy dog")
+
+	store.Store(ctx, 1, value)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		store.Load(ctx, 1)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // Freshness enumerates freshness.
@@ -18,6 +19,10 @@ func (f Freshness) String() string {
 		return "fresh"
 	case Stale:
 		return "stale"
+	case MustRevalidate:
+		return "must-revalidate"
+	case StaleWhileRevalidate:
+		return "stale-while-revalidate"
 	case Transparent:
 		return "transprent"
 	default:
@@ -29,6 +34,16 @@ func (f Freshness) String() string {
 const (
 	Fresh Freshness = iota
 	Stale
+	// MustRevalidate indicates that the stored response is no longer
+	// fresh but carries a validator (ETag or Last-Modified) that the
+	// cache should try against the origin before falling back to a
+	// full request, see https://tools.ietf.org/html/rfc7234#section-4.3.
+	MustRevalidate
+	// StaleWhileRevalidate indicates that the stored response is no
+	// longer fresh, but within its "stale-while-revalidate" window, so
+	// it can be returned immediately while a revalidation happens in
+	// the background, see https://tools.ietf.org/html/rfc5861#section-3.
+	StaleWhileRevalidate
 	Transparent
 )
 
@@ -50,6 +65,14 @@ type Strategy interface {
 	// The method is called just before a cached response
 	// is returned from the cache.
 	fresh(resp *http.Response) Freshness
+
+	// Lifetime returns resp's freshness lifetime under the strategy's
+	// own configuration (e.g. shared-cache / heuristic settings), and
+	// whether one could be determined.
+	//
+	// The method is called wherever a lifetime needs recomputing
+	// outside of `fresh` itself, e.g. a stale-if-error check.
+	lifetime(resp *http.Response) (time.Duration, bool)
 }
 
 // Storage represents the cache storage.
@@ -69,6 +92,12 @@ type Storage interface {
 	//
 	// The method returns the full response, as stored by `Store()`.
 	Load(ctx context.Context, key uint64) ([]byte, error)
+
+	// Delete removes the entry stored under key, if any.
+	//
+	// The method is a no-op, and returns a nil error, if no entry
+	// is stored under key.
+	Delete(ctx context.Context, key uint64) error
 }
 
 // Client represents an HTTP client.
@@ -119,11 +148,27 @@ func WithClient(client Client) Option {
 	}
 }
 
+// WithSingleflight enables, or disables, coalescing of concurrent
+// cacheable requests for the same key into a single upstream request.
+//
+// Disabled by default.
+func WithSingleflight(enabled bool) Option {
+	return func(c *Cache) error {
+		if enabled {
+			c.sf = &singleflightGroup{}
+		} else {
+			c.sf = nil
+		}
+		return nil
+	}
+}
+
 // Cache implements an HTTP cache.
 type Cache struct {
 	storage  Storage
 	strategy Strategy
 	client   Client
+	sf       *singleflightGroup
 }
 
 // NewCache returns a new cache with the given options.
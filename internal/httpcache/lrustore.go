@@ -0,0 +1,173 @@
+package httpcache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// LRUStore wraps a Storage, evicting the least-recently-used entries
+// once the configured byte budget is exceeded.
+//
+// Only entries stored or loaded through the LRUStore are counted
+// against the budget and can be evicted; entries the wrapped Storage
+// already held are not, until they are loaded through the LRUStore.
+type LRUStore struct {
+	// Storage is the wrapped backend.
+	Storage Storage
+
+	// MaxBytes is the maximum total size, in bytes, of stored values
+	// before the oldest entries are evicted.
+	//
+	// If <= 0, defaults to 64MiB.
+	MaxBytes int64
+
+	once   sync.Once
+	mu     sync.Mutex
+	order  *list.List
+	elems  map[uint64]*list.Element
+	sizes  map[uint64]int64
+	bytes  int64
+	hits   uint64
+	misses uint64
+	evicts uint64
+}
+
+// NewLRUStore returns a store wrapping storage, bounded to maxBytes.
+func NewLRUStore(storage Storage, maxBytes int64) *LRUStore {
+	var l = &LRUStore{Storage: storage, MaxBytes: maxBytes}
+	l.init()
+	return l
+}
+
+// Init lazily sets up the bookkeeping structures, so a zero-value
+// LRUStore{Storage: ...} works without calling NewLRUStore.
+func (l *LRUStore) init() {
+	l.once.Do(func() {
+		l.order = list.New()
+		l.elems = make(map[uint64]*list.Element)
+		l.sizes = make(map[uint64]int64)
+	})
+}
+
+// MaxBytes returns the effective byte budget.
+func (l *LRUStore) maxBytes() int64 {
+	if l.MaxBytes > 0 {
+		return l.MaxBytes
+	}
+	return 64 << 20
+}
+
+// Store implementation.
+func (l *LRUStore) Store(ctx context.Context, key uint64, value []byte) error {
+	if err := l.Storage.Store(ctx, key, value); err != nil {
+		return err
+	}
+
+	l.init()
+	l.mu.Lock()
+	l.touch(key, int64(len(value)))
+	var evicted = l.evict()
+	l.mu.Unlock()
+
+	for _, k := range evicted {
+		l.Storage.Delete(ctx, k)
+	}
+
+	return nil
+}
+
+// Load implementation.
+func (l *LRUStore) Load(ctx context.Context, key uint64) ([]byte, error) {
+	value, err := l.Storage.Load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	l.init()
+	l.mu.Lock()
+	if value == nil {
+		l.misses++
+	} else {
+		l.hits++
+		l.touch(key, int64(len(value)))
+	}
+	l.mu.Unlock()
+
+	return value, nil
+}
+
+// Delete implementation.
+func (l *LRUStore) Delete(ctx context.Context, key uint64) error {
+	if err := l.Storage.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	l.init()
+	l.mu.Lock()
+	l.remove(key)
+	l.mu.Unlock()
+
+	return nil
+}
+
+// Stats returns the store's hit/miss/eviction/byte counters.
+func (l *LRUStore) Stats() Stats {
+	l.init()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return Stats{
+		Hits:      l.hits,
+		Misses:    l.misses,
+		Evictions: l.evicts,
+		Bytes:     l.bytes,
+	}
+}
+
+// Touch records key as the most-recently-used entry, of size bytes.
+//
+// The caller must hold mu.
+func (l *LRUStore) touch(key uint64, size int64) {
+	if e, ok := l.elems[key]; ok {
+		l.order.MoveToBack(e)
+		l.bytes += size - l.sizes[key]
+		l.sizes[key] = size
+		return
+	}
+
+	l.elems[key] = l.order.PushBack(key)
+	l.sizes[key] = size
+	l.bytes += size
+}
+
+// Remove drops key from the LRU bookkeeping.
+//
+// The caller must hold mu.
+func (l *LRUStore) remove(key uint64) {
+	if e, ok := l.elems[key]; ok {
+		l.order.Remove(e)
+		l.bytes -= l.sizes[key]
+		delete(l.elems, key)
+		delete(l.sizes, key)
+	}
+}
+
+// Evict removes least-recently-used entries until the store is back
+// within its byte budget, returning the evicted keys.
+//
+// The caller must hold mu.
+func (l *LRUStore) evict() (evicted []uint64) {
+	for l.bytes > l.maxBytes() {
+		var front = l.order.Front()
+		if front == nil {
+			break
+		}
+
+		var key = front.Value.(uint64)
+		l.remove(key)
+		l.evicts++
+		evicted = append(evicted, key)
+	}
+	return
+}
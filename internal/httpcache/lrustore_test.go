@@ -0,0 +1,85 @@
+package httpcache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUStore(t *testing.T) {
+	t.Run("store and load", func(t *testing.T) {
+		var assert = require.New(t)
+		var store = NewLRUStore(&Memstore{}, 1024)
+		var ctx = context.Background()
+
+		assert.NoError(store.Store(ctx, 1, []byte("hello")))
+
+		v, err := store.Load(ctx, 1)
+		assert.NoError(err)
+		assert.Equal([]byte("hello"), v)
+	})
+
+	t.Run("evicts least-recently-used entries over budget", func(t *testing.T) {
+		var assert = require.New(t)
+		var store = NewLRUStore(&Memstore{}, 10)
+		var ctx = context.Background()
+
+		assert.NoError(store.Store(ctx, 1, []byte("0123456789")))
+		assert.NoError(store.Store(ctx, 2, []byte("0123456789")))
+
+		v, err := store.Load(ctx, 1)
+		assert.NoError(err)
+		assert.Nil(v, "key 1 should have been evicted")
+
+		v, err = store.Load(ctx, 2)
+		assert.NoError(err)
+		assert.Equal([]byte("0123456789"), v)
+
+		assert.Equal(uint64(1), store.Stats().Evictions)
+	})
+
+	t.Run("touching an entry protects it from eviction", func(t *testing.T) {
+		var assert = require.New(t)
+		var store = NewLRUStore(&Memstore{}, 10)
+		var ctx = context.Background()
+
+		assert.NoError(store.Store(ctx, 1, []byte("12345")))
+		assert.NoError(store.Store(ctx, 2, []byte("12345")))
+
+		// Accessing key 1 makes it the most-recently-used.
+		_, err := store.Load(ctx, 1)
+		assert.NoError(err)
+
+		assert.NoError(store.Store(ctx, 3, []byte("12345")))
+
+		v, err := store.Load(ctx, 2)
+		assert.NoError(err)
+		assert.Nil(v, "key 2 should have been evicted, not key 1")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		var assert = require.New(t)
+		var store = NewLRUStore(&Memstore{}, 1024)
+		var ctx = context.Background()
+
+		assert.NoError(store.Store(ctx, 1, []byte("hello")))
+		assert.NoError(store.Delete(ctx, 1))
+
+		v, err := store.Load(ctx, 1)
+		assert.NoError(err)
+		assert.Nil(v)
+		assert.Zero(store.Stats().Bytes)
+	})
+}
+
+func BenchmarkLRUStore_Store(b *testing.B) {
+	var store = NewLRUStore(&Memstore{}, 1<<20)
+	var ctx = context.Background()
+	var value = []byte("the quick brown fox jumps over the lazy dog")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		store.Store(ctx, uint64(i), value)
+	}
+}
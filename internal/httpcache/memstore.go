@@ -23,3 +23,9 @@ func (m *Memstore) Load(ctx context.Context, key uint64) ([]byte, error) {
 	}
 	return nil, nil
 }
+
+// Delete implementation.
+func (m *Memstore) Delete(ctx context.Context, key uint64) error {
+	m.c.Delete(key)
+	return nil
+}
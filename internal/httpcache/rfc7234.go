@@ -2,25 +2,71 @@ package httpcache
 
 import (
 	"net/http"
+	"time"
+
+	"github.com/yields/ant/internal/httpcache/cachecontrol"
 )
 
+// HeuristicWarnAge is the current-age threshold past which a
+// heuristically-fresh response served from cache carries a
+// "113 - Heuristic Expiration" warning.
+//
+// https://tools.ietf.org/html/rfc7234#section-5.5.4
+const heuristicWarnAge = 24 * time.Hour
+
 // RFC7234 implements the standard cache strategy.
 //
 // https://tools.ietf.org/html/rfc7234
-type RFC7234 struct{}
+type RFC7234 struct {
+	// Shared, when true, configures the strategy as a shared (proxy)
+	// cache: responses marked "private" are not stored, and
+	// "s-maxage" takes precedence over "max-age".
+	//
+	// The zero value is a private cache, matching a single-user
+	// client such as ant.
+	Shared bool
+
+	// HeuristicFraction is the fraction of (Date - Last-Modified)
+	// used as a freshness lifetime for responses that carry neither
+	// "max-age"/"s-maxage" nor "Expires" (Section 4.2.2).
+	//
+	// If <= 0, defaults to 0.1 (10%).
+	HeuristicFraction float64
+
+	// HeuristicMax caps the heuristic freshness lifetime.
+	//
+	// If <= 0, defaults to 24h.
+	HeuristicMax time.Duration
+}
+
+// HeuristicFraction returns the effective heuristic fraction.
+func (s RFC7234) heuristicFraction() float64 {
+	if s.HeuristicFraction > 0 {
+		return s.HeuristicFraction
+	}
+	return 0.1
+}
+
+// HeuristicMax returns the effective heuristic cap.
+func (s RFC7234) heuristicMax() time.Duration {
+	if s.HeuristicMax > 0 {
+		return s.HeuristicMax
+	}
+	return 24 * time.Hour
+}
 
 // Cache implementation.
 //
 // The method returns true if the request may use a cached
 // response, or if it allows caching.
-func (RFC7234) cache(req *http.Request) bool {
-	return (req.Method == "GET" || req.Method == "HEAD") && !nostore(req.Header)
+func (s RFC7234) cache(req *http.Request) bool {
+	return (req.Method == "GET" || req.Method == "HEAD") && !cachecontrol.Parse(req.Header).NoStore()
 }
 
 // Store implementation.
 //
 // https://tools.ietf.org/html/rfc7234#section-3
-func (RFC7234) store(resp *http.Response) bool {
+func (s RFC7234) store(resp *http.Response) bool {
 	var req = resp.Request
 
 	// The request method is cacheable.
@@ -41,21 +87,41 @@ func (RFC7234) store(resp *http.Response) bool {
 		return false
 	}
 
+	var (
+		reqcc = cachecontrol.Parse(req.Header)
+		rescc = cachecontrol.Parse(resp.Header)
+	)
+
 	// the "no-store" cache directive (see Section 5.2) does not appear
 	// in request or response header fields.
-	if nostore(req.Header) || nostore(resp.Header) {
+	if reqcc.NoStore() || rescc.NoStore() {
 		return false
 	}
 
-	// The response has an explicit "lifetime" duration.
-	age, ok := lifetime(resp)
+	// a shared cache must not store a response marked "private"
+	// (Section 5.2.2.7).
+	if s.Shared {
+		if _, private := rescc.Private(); private {
+			return false
+		}
+	}
+
+	// The response has an explicit, or heuristic, "lifetime" duration
+	// (see Section 4.2.2).
+	age, ok := s.lifetime(resp)
 	return ok && age > 0
 }
 
+// Lifetime implementation.
+func (s RFC7234) lifetime(resp *http.Response) (time.Duration, bool) {
+	age, ok, _ := lifetime(resp, s.Shared, s.heuristicFraction(), s.heuristicMax())
+	return age, ok
+}
+
 // Fresh implementation.
 //
 // https://tools.ietf.org/html/rfc7234#section-4
-func (RFC7234) fresh(resp *http.Response) Freshness {
+func (s RFC7234) fresh(resp *http.Response) Freshness {
 	var req = resp.Request
 
 	// selecting header fields nominated by the stored response (if any)
@@ -66,8 +132,8 @@ func (RFC7234) fresh(resp *http.Response) Freshness {
 
 	// Parse request and response directives.
 	var (
-		reqd = directivesFrom(req.Header)
-		resd = directivesFrom(resp.Header)
+		reqcc = cachecontrol.Parse(req.Header)
+		rescc = cachecontrol.Parse(resp.Header)
 	)
 
 	// the presented request does not contain the no-cache pragma
@@ -76,15 +142,62 @@ func (RFC7234) fresh(resp *http.Response) Freshness {
 	//
 	// the stored response does not contain the no-cache cache directive
 	// (Section 5.2.2.2), unless it is successfully validated (Section 4.3)
-	if reqd.has("no-cache") || resd.has("no-cache") {
-		return Stale
+	if reqcc.Has("no-cache") || rescc.Has("no-cache") {
+		return s.revalidateOrStale(resp)
+	}
+
+	life, ok, heuristic := lifetime(resp, s.Shared, s.heuristicFraction(), s.heuristicMax())
+	if !ok {
+		return s.revalidateOrStale(resp)
+	}
+
+	var cur = age(resp)
+
+	// the client-supplied "min-fresh" (Section 5.2.1.3) requires more
+	// remaining freshness lifetime than what is left.
+	if mf, ok := reqcc.MinFresh(); ok && life-cur < mf {
+		return s.revalidateOrStale(resp)
 	}
 
 	// the stored response is either fresh (see Section 4.2).
-	if age, ok := lifetime(resp); ok && age > 0 {
+	if cur < life {
+		// a heuristically-fresh response older than 24h carries a
+		// warning, since the client did not explicitly ask for it
+		// to be cached this long (Section 5.5.4).
+		if heuristic && cur > heuristicWarnAge {
+			resp.Header.Set("Warning", `113 - "Heuristic Expiration"`)
+		}
 		return Fresh
 	}
 
+	// the client is willing to accept a stale response
+	// (Section 5.2.1.1), unless the stored response carries
+	// "must-revalidate" - or "proxy-revalidate" for a shared cache -
+	// which exists specifically to stop a client's "max-stale" from
+	// overriding server-mandated revalidation (Section 5.2.2.1).
+	if !rescc.Has("must-revalidate") && !(s.Shared && rescc.ProxyRevalidate()) {
+		if ms, unlimited, ok := reqcc.MaxStale(); ok && (unlimited || cur-life <= ms) {
+			return Fresh
+		}
+	}
+
+	// the response carries "stale-while-revalidate"
+	// (https://tools.ietf.org/html/rfc5861#section-3), and is within
+	// that window: serve it now, revalidating in the background.
+	if swr, ok := rescc.StaleWhileRevalidate(); ok && cur-life <= swr {
+		return StaleWhileRevalidate
+	}
+
 	// validate (see Section 4.3).
+	return s.revalidateOrStale(resp)
+}
+
+// RevalidateOrStale returns MustRevalidate when resp carries a
+// validator that can be used to conditionally revalidate it, or Stale
+// when the cache must re-fetch it from scratch.
+func (s RFC7234) revalidateOrStale(resp *http.Response) Freshness {
+	if validators(resp.Header) {
+		return MustRevalidate
+	}
 	return Stale
 }
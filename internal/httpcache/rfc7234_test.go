@@ -127,6 +127,20 @@ func TestRFC7234(t *testing.T) {
 				},
 				store: false,
 			},
+			{
+				title: "GET response heuristic lifetime from last-modified",
+				resp: &http.Response{
+					StatusCode: 200,
+					Header: http.Header{
+						"Date":          []string{now.Format(time.RFC1123)},
+						"Last-Modified": []string{now.Add(-10 * time.Hour).Format(time.RFC1123)},
+					},
+					Request: &http.Request{
+						Method: "GET",
+					},
+				},
+				store: true,
+			},
 		}
 
 		for _, c := range cases {
@@ -139,6 +153,22 @@ func TestRFC7234(t *testing.T) {
 		}
 	})
 
+	t.Run("shared store", func(t *testing.T) {
+		var assert = require.New(t)
+		var strategy = RFC7234{Shared: true}
+		var resp = &http.Response{
+			StatusCode: 200,
+			Header: http.Header{
+				"Cache-Control": []string{"max-age=60, private"},
+			},
+			Request: &http.Request{
+				Method: "GET",
+			},
+		}
+
+		assert.False(strategy.store(resp))
+	})
+
 	t.Run("fresh", func(t *testing.T) {
 		var now = time.Now()
 		var cases = []struct {
@@ -197,6 +227,75 @@ func TestRFC7234(t *testing.T) {
 				},
 				fresh: Stale,
 			},
+			{
+				title: "fresh via min-fresh",
+				resp: &http.Response{
+					Request: &http.Request{
+						Header: http.Header{
+							"Cache-Control": []string{"min-fresh=2"},
+						},
+					},
+					Header: http.Header{
+						"Date":          []string{now.Format(time.RFC1123)},
+						"Cache-Control": []string{"max-age=5"},
+					},
+				},
+				fresh: Fresh,
+			},
+			{
+				title: "fresh via max-stale",
+				resp: &http.Response{
+					Request: &http.Request{
+						Header: http.Header{
+							"Cache-Control": []string{"max-stale=60"},
+						},
+					},
+					Header: http.Header{
+						"Date":          []string{now.Add(-time.Minute).Format(time.RFC1123)},
+						"Cache-Control": []string{"max-age=5"},
+					},
+				},
+				fresh: Fresh,
+			},
+			{
+				title: "must-revalidate when stale with a validator",
+				resp: &http.Response{
+					Request: &http.Request{},
+					Header: http.Header{
+						"Date":          []string{now.Add(-time.Minute).Format(time.RFC1123)},
+						"Cache-Control": []string{"max-age=5"},
+						"Etag":          []string{`"v1"`},
+					},
+				},
+				fresh: MustRevalidate,
+			},
+			{
+				title: "stale-while-revalidate",
+				resp: &http.Response{
+					Request: &http.Request{},
+					Header: http.Header{
+						"Date":          []string{now.Add(-time.Minute).Format(time.RFC1123)},
+						"Cache-Control": []string{"max-age=5, stale-while-revalidate=120"},
+					},
+				},
+				fresh: StaleWhileRevalidate,
+			},
+			{
+				title: "must-revalidate overrides max-stale",
+				resp: &http.Response{
+					Request: &http.Request{
+						Header: http.Header{
+							"Cache-Control": []string{"max-stale=60"},
+						},
+					},
+					Header: http.Header{
+						"Date":          []string{now.Add(-time.Minute).Format(time.RFC1123)},
+						"Cache-Control": []string{"max-age=5, must-revalidate"},
+						"Etag":          []string{`"v1"`},
+					},
+				},
+				fresh: MustRevalidate,
+			},
 		}
 
 		for _, c := range cases {
@@ -208,4 +307,42 @@ func TestRFC7234(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("proxy-revalidate overrides max-stale for a shared cache", func(t *testing.T) {
+		var assert = require.New(t)
+		var strategy = RFC7234{Shared: true}
+		var now = time.Now()
+		var resp = &http.Response{
+			Request: &http.Request{
+				Header: http.Header{
+					"Cache-Control": []string{"max-stale=60"},
+				},
+			},
+			Header: http.Header{
+				"Date":          []string{now.Add(-time.Minute).Format(time.RFC1123)},
+				"Cache-Control": []string{"max-age=5, proxy-revalidate"},
+				"Etag":          []string{`"v1"`},
+			},
+		}
+
+		assert.Equal(MustRevalidate, strategy.fresh(resp))
+	})
+
+	t.Run("heuristic freshness", func(t *testing.T) {
+		var assert = require.New(t)
+		var strategy = RFC7234{HeuristicFraction: 0.5, HeuristicMax: 72 * time.Hour}
+		var now = time.Now()
+		var date = now.Add(-30 * time.Hour)
+
+		var resp = &http.Response{
+			Request: &http.Request{},
+			Header: http.Header{
+				"Date":          []string{date.Format(time.RFC1123)},
+				"Last-Modified": []string{date.Add(-100 * time.Hour).Format(time.RFC1123)},
+			},
+		}
+
+		assert.Equal(Fresh, strategy.fresh(resp))
+		assert.Equal(`113 - "Heuristic Expiration"`, resp.Header.Get("Warning"))
+	})
 }
@@ -0,0 +1,95 @@
+package httpcache
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// SingleflightGroup coalesces concurrent fetches of the same cache key
+// into a single upstream request.
+//
+// The first caller for a key (the leader) runs lead and stores its
+// result; any caller that arrives while the leader is in flight (a
+// follower) waits on the key's condition variable instead of issuing
+// its own request, then tries reload - which re-runs the `Storage.Load`
+// path - falling back to a copy of the leader's response if reload
+// comes up empty (e.g. the response wasn't cacheable).
+type singleflightGroup struct {
+	calls sync.Map // map[uint64]*singleflightCall
+}
+
+// SingleflightCall tracks the in-flight, or completed, call for a key.
+type singleflightCall struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	done bool
+	resp *http.Response
+	body []byte
+	err  error
+}
+
+func newSingleflightCall() *singleflightCall {
+	var c = &singleflightCall{}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Do runs lead if the caller is the first to arrive for key, otherwise
+// it waits for the in-flight call to finish and returns reload's
+// result, falling back to an independent copy of the leader's
+// response.
+//
+// A follower's reload is never allowed to cancel the leader's lead,
+// since lead only ever runs once, driven by the leader's own request.
+func (g *singleflightGroup) do(key uint64, lead, reload func() (*http.Response, error)) (*http.Response, error) {
+	actual, loaded := g.calls.LoadOrStore(key, newSingleflightCall())
+	var call = actual.(*singleflightCall)
+
+	if !loaded {
+		resp, err := lead()
+
+		var body []byte
+		if err == nil && resp != nil {
+			body, _ = io.ReadAll(resp.Body)
+			resp.Body.Close()
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		call.mu.Lock()
+		call.resp, call.body, call.err = resp, body, err
+		call.done = true
+		call.cond.Broadcast()
+		call.mu.Unlock()
+
+		g.calls.Delete(key)
+		return resp, err
+	}
+
+	call.mu.Lock()
+	for !call.done {
+		call.cond.Wait()
+	}
+	var resp, body, err = call.resp, call.body, call.err
+	call.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if fresh, ferr := reload(); ferr == nil && fresh != nil {
+		return fresh, nil
+	}
+
+	return cloneResponse(resp, body), nil
+}
+
+// CloneResponse returns a shallow copy of resp, with its own header map
+// and a body independently readable from body.
+func cloneResponse(resp *http.Response, body []byte) *http.Response {
+	var clone = *resp
+	clone.Header = resp.Header.Clone()
+	clone.Body = io.NopCloser(bytes.NewReader(body))
+	return &clone
+}
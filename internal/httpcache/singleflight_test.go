@@ -0,0 +1,156 @@
+package httpcache
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheSingleflight(t *testing.T) {
+	t.Run("coalesces concurrent misses into a single upstream request", func(t *testing.T) {
+		var assert = require.New(t)
+		var calls int32
+		var release = make(chan struct{})
+
+		var cache, err = NewCache(
+			WithStorage(&Memstore{}),
+			WithSingleflight(true),
+			WithClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+
+				var rec = httptest.NewRecorder()
+				rec.Header().Set("Cache-Control", "max-age=60")
+				rec.Header().Set("Date", time.Now().UTC().Format(time.RFC1123))
+				rec.WriteHeader(200)
+				rec.Body = bytes.NewBufferString("hello")
+				var resp = rec.Result()
+				resp.Request = req
+				return resp, nil
+			})),
+		)
+		assert.NoError(err)
+
+		const n = 10
+		var wg sync.WaitGroup
+		var bodies = make([]string, n)
+
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				var req = newRequest(t)
+				req.Header = http.Header{}
+
+				resp, err := cache.Do(req)
+				assert.NoError(err)
+				body, _ := io.ReadAll(resp.Body)
+				bodies[i] = string(body)
+			}(i)
+		}
+
+		close(release)
+		wg.Wait()
+
+		assert.EqualValues(1, atomic.LoadInt32(&calls))
+		for i, body := range bodies {
+			assert.Equal("hello", body, "response %d", i)
+		}
+	})
+
+	t.Run("followers get stripped bookkeeping headers and a recomputed Age", func(t *testing.T) {
+		var assert = require.New(t)
+		var release = make(chan struct{})
+
+		var cache, err = NewCache(
+			WithStorage(&Memstore{}),
+			WithSingleflight(true),
+			WithClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				<-release
+
+				var rec = httptest.NewRecorder()
+				rec.Header().Set("Cache-Control", "max-age=60")
+				rec.Header().Set("Date", time.Now().UTC().Format(time.RFC1123))
+				rec.WriteHeader(200)
+				rec.Body = bytes.NewBufferString("hello")
+				var resp = rec.Result()
+				resp.Request = req
+				return resp, nil
+			})),
+		)
+		assert.NoError(err)
+
+		const n = 5
+		var wg sync.WaitGroup
+		var resps = make([]*http.Response, n)
+
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				var req = newRequest(t)
+				req.Header = http.Header{}
+				resp, err := cache.Do(req)
+				assert.NoError(err)
+				resps[i] = resp
+			}(i)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		var withAge int
+		for i, resp := range resps {
+			assert.Empty(resp.Header.Get(headerRequestTime), "response %d", i)
+			assert.Empty(resp.Header.Get(headerResponseTime), "response %d", i)
+			if resp.Header.Get("Age") != "" {
+				withAge++
+			}
+		}
+
+		// Every follower's reload recomputes Age; only the leader's own
+		// response, returned straight from `store`, never gets one.
+		assert.Equal(n-1, withAge)
+	})
+
+	t.Run("disabled by default, each miss hits the client", func(t *testing.T) {
+		var assert = require.New(t)
+		var calls int32
+
+		var cache, err = NewCache(
+			WithStorage(&Memstore{}),
+			WithClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&calls, 1)
+				var rec = httptest.NewRecorder()
+				rec.WriteHeader(204)
+				var resp = rec.Result()
+				resp.Request = req
+				return resp, nil
+			})),
+		)
+		assert.NoError(err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var req = newRequest(t)
+				req.Header = http.Header{}
+				_, err := cache.Do(req)
+				assert.NoError(err)
+			}()
+		}
+		wg.Wait()
+
+		assert.EqualValues(3, atomic.LoadInt32(&calls))
+	})
+}
@@ -0,0 +1,17 @@
+package httpcache
+
+// Stats reports counters for a Storage backend.
+type Stats struct {
+	// Hits is the number of successful Load calls.
+	Hits uint64
+
+	// Misses is the number of Load calls that found nothing.
+	Misses uint64
+
+	// Evictions is the number of entries removed to stay within a
+	// backend's size budget, if it has one.
+	Evictions uint64
+
+	// Bytes is the total size, in bytes, of the values currently held.
+	Bytes int64
+}
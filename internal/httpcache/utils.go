@@ -2,9 +2,10 @@ package httpcache
 
 import (
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
+
+	"github.com/yields/ant/internal/httpcache/cachecontrol"
 )
 
 // Matches ensures that the given request and response match.
@@ -24,58 +25,6 @@ func matches(req *http.Request, resp *http.Response) bool {
 	return true
 }
 
-// Nocache returns true if the no-cache is set.
-func nocache(h http.Header) bool {
-	var c = h.Get("Cache-Control")
-	var p = h.Get("Pragma")
-
-	for _, v := range split(c, ",") {
-		if v == "no-cache" {
-			return true
-		}
-	}
-
-	for _, v := range split(p, ",") {
-		if v == "no-cache" {
-			return true
-		}
-	}
-
-	return false
-}
-
-// Nostore returns true if no-store is set.
-func nostore(h http.Header) bool {
-	var c = h.Get("Cache-Control")
-
-	for _, v := range split(c, ",") {
-		if v == "no-store" {
-			return true
-		}
-	}
-
-	return false
-}
-
-// Maxage returns the cache-control max-age.
-//
-// When max-age does not exist, ok is false.
-func maxage(h http.Header) (age time.Duration, ok bool) {
-	var c = h.Get("Cache-Control")
-
-	for _, d := range split(c, ",") {
-		if strings.HasPrefix(d, "max-age") {
-			if j := strings.IndexByte(d, '='); j != -1 {
-				n, _ := strconv.ParseInt(d[j+1:], 10, 64)
-				age, ok = time.Duration(n)*time.Second, true
-				break
-			}
-		}
-	}
-
-	return
-}
-
 // Expires returns the expires timestamp.
 //
 // When expires does not exist or is zero, ok is false.
@@ -111,19 +60,77 @@ func split(str, sep string) (ret []string) {
 	return
 }
 
+// Etag returns the response's validator.
+//
+// When no ETag is present, ok is false.
+func etag(h http.Header) (string, bool) {
+	var v = h.Get("Etag")
+	return v, v != ""
+}
+
+// LastModified returns the last-modified timestamp.
+//
+// When last-modified does not exist or is zero, ok is false.
+func lastModified(h http.Header) (lastModified time.Time, ok bool) {
+	if v := h.Get("Last-Modified"); v != "" {
+		t, err := time.Parse(time.RFC1123, v)
+		lastModified, ok = t, (err == nil && !t.IsZero())
+	}
+	return
+}
+
+// Validators returns true if the response carries a validator that
+// can be used to conditionally revalidate it.
+func validators(h http.Header) bool {
+	if _, ok := etag(h); ok {
+		return true
+	}
+	_, ok := lastModified(h)
+	return ok
+}
+
 // Lifetime returns the lifetime duration of the response.
 //
+// When shared is true, the "s-maxage" directive takes precedence over
+// "max-age" (https://tools.ietf.org/html/rfc7234#section-5.2.2.9).
+//
+// When the response carries neither an explicit lifetime nor
+// "Expires", a heuristic lifetime is computed as heuristicFraction of
+// the time between "Last-Modified" and "Date", capped at
+// heuristicMax; heuristic is true in that case
+// (https://tools.ietf.org/html/rfc7234#section-4.2.2).
+//
 // https://tools.ietf.org/html/rfc7234#section-4.2.1
-func lifetime(resp *http.Response) (time.Duration, bool) {
-	if age, ok := maxage(resp.Header); ok {
-		return age, true
+func lifetime(resp *http.Response, shared bool, heuristicFraction float64, heuristicMax time.Duration) (age time.Duration, ok, heuristic bool) {
+	var cc = cachecontrol.Parse(resp.Header)
+
+	if shared {
+		if age, ok := cc.SMaxAge(); ok {
+			return age, true, false
+		}
+	}
+
+	if age, ok := cc.MaxAge(); ok {
+		return age, true, false
 	}
 
 	if exp, ok := expires(resp.Header); ok {
 		if date, ok := date(resp.Header); ok {
-			return exp.Sub(date), true
+			return exp.Sub(date), true, false
+		}
+	}
+
+	if lm, ok := lastModified(resp.Header); ok {
+		if d, ok := date(resp.Header); ok {
+			var h = time.Duration(float64(d.Sub(lm)) * heuristicFraction)
+			if h > heuristicMax {
+				h = heuristicMax
+			}
+			if h > 0 {
+				return h, true, true
+			}
 		}
 	}
 
-	return -1, false
+	return -1, false, false
 }